@@ -0,0 +1,33 @@
+package atreugo
+
+import "github.com/valyala/fasthttp"
+
+// Handler is the fasthttp.RequestHandler for the whole server.
+//
+// It attaches the request-scoped Logger returned by ctx.Logger() to ctx as a
+// UserValue, so it is visible to any *RequestCtx later built around the same
+// underlying ctx further down the routing path, and dispatches to the
+// matching virtual host or the main Router. It is assigned as the underlying
+// fasthttp.Server's Handler.
+func (s *Atreugo) Handler(ctx *fasthttp.RequestCtx) {
+	actx := &RequestCtx{RequestCtx: ctx}
+
+	// prepareServe resolves Config.Logger into s.Router.cfg.logger once
+	// before the engine starts accepting connections. Fall back to
+	// resolving it here too, so Handler still behaves correctly if it's
+	// ever invoked without going through Serve/ListenAndServeTLS first
+	// (e.g. in tests).
+	base := s.Router.cfg.logger
+	if base == nil {
+		base = resolveLogger(s.cfg.Logger)
+	}
+
+	setLogger(ctx, requestLogger(base, actx))
+
+	if h, ok := s.virtualHostHandler(ctx); ok {
+		h(ctx)
+		return
+	}
+
+	s.router.Handler(ctx)
+}
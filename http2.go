@@ -0,0 +1,131 @@
+package atreugo
+
+import (
+	"crypto/tls"
+	"net"
+	"time"
+
+	"github.com/dgrr/http2"
+)
+
+// HTTP2Config configures HTTP/2 support for the server.
+//
+// HTTP/2 requires TLS to be enabled, because fasthttp itself only speaks HTTP/1.1
+// and github.com/dgrr/http2 negotiates h2 through the TLS ALPN handshake.
+// Use ServeH2C to serve cleartext HTTP/2 instead, for example behind a
+// TLS-terminating proxy.
+type HTTP2Config struct {
+	// Enable turns on HTTP/2 support over TLS.
+	Enable bool
+
+	// PingInterval is the amount of time to wait before sending a ping
+	// to a client for keep-alive purposes. Disabled if zero.
+	PingInterval time.Duration
+
+	// MaxConcurrentStreams is the maximum number of concurrent streams
+	// a peer is allowed to open on a single connection.
+	//
+	// DefaultMaxConcurrentStreams from dgrr/http2 is used if not set.
+	MaxConcurrentStreams int
+
+	// Debug enables verbose logging of the HTTP/2 connections.
+	Debug bool
+}
+
+func (s *Atreugo) http2ServerConfig() http2.ServerConfig {
+	return http2.ServerConfig{
+		PingInterval:         s.cfg.HTTP2.PingInterval,
+		MaxConcurrentStreams: s.cfg.HTTP2.MaxConcurrentStreams,
+		Debug:                s.cfg.HTTP2.Debug,
+	}
+}
+
+// configureHTTP2 wires up h2 support on engine when TLS and HTTP2 are enabled.
+//
+// It must be called before the TLS listener starts accepting connections, since
+// it appends "h2" to engine.TLSConfig.NextProtos for ALPN negotiation. engine.TLSConfig
+// is what ServeTLS actually hands to the TLS handshake, so the ALPN entry is added
+// there directly instead of to cfg.TLSConfig, which ServeTLS never reads.
+func (s *Atreugo) configureHTTP2() error {
+	if !s.cfg.TLSEnable || !s.cfg.HTTP2.Enable {
+		return nil
+	}
+
+	if err := http2.ConfigureServer(s.engine, s.http2ServerConfig()); err != nil {
+		return err
+	}
+
+	if s.engine.TLSConfig == nil {
+		if s.cfg.TLSConfig != nil {
+			// Config.TLSConfig is documented as cloned rather than mutated in
+			// place, so callers can keep using/sharing that *tls.Config
+			// elsewhere without seeing "h2" appended to their own object.
+			s.engine.TLSConfig = s.cfg.TLSConfig.Clone()
+		} else {
+			s.engine.TLSConfig = new(tls.Config)
+		}
+	}
+
+	s.engine.TLSConfig.NextProtos = append(s.engine.TLSConfig.NextProtos, "h2")
+
+	return nil
+}
+
+// prepareServe applies the setup that must run before the engine starts
+// accepting connections, regardless of which Serve* variant is used.
+func (s *Atreugo) prepareServe() error {
+	if err := s.configureHTTP2(); err != nil {
+		return err
+	}
+
+	s.Router.cfg.maxRequestBodySize = s.cfg.MaxRequestBodySize
+	s.Router.cfg.logger = resolveLogger(s.cfg.Logger)
+
+	if s.Router.cfg.streamRequestBody {
+		s.engine.StreamRequestBody = true
+	}
+
+	return nil
+}
+
+// ServeH2C serves cleartext HTTP/2 (h2c) on ln, upgrading plain HTTP/1.1
+// connections that announce the h2c upgrade.
+//
+// This is meant for deployments where TLS is terminated by a proxy in front
+// of Atreugo and HTTP/2 still needs to reach the application over the
+// cleartext leg.
+func (s *Atreugo) ServeH2C(ln net.Listener) error {
+	if err := http2.ConfigureServer(s.engine, s.http2ServerConfig()); err != nil {
+		return err
+	}
+
+	upgrader := http2.NewH2CUpgrader(s.engine.Handler)
+	s.engine.Handler = upgrader.Upgrade
+
+	return s.Serve(ln)
+}
+
+// ListenAndServeTLS serves HTTPS requests on Config.Addr, optionally
+// negotiating HTTP/2 through ALPN when Config.HTTP2.Enable is set.
+func (s *Atreugo) ListenAndServeTLS(certFile, certKey string) error {
+	if err := s.prepareServe(); err != nil {
+		return err
+	}
+
+	ln, err := s.newListener()
+	if err != nil {
+		return err
+	}
+
+	return s.engine.ServeTLS(ln, certFile, certKey)
+}
+
+// Serve serves incoming connections accepted from ln, configuring HTTP/2
+// on the underlying engine first when TLS and HTTP2 are enabled.
+func (s *Atreugo) Serve(ln net.Listener) error {
+	if err := s.prepareServe(); err != nil {
+		return err
+	}
+
+	return s.engine.Serve(ln)
+}
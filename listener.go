@@ -0,0 +1,54 @@
+package atreugo
+
+import (
+	"net"
+
+	"github.com/valyala/fasthttp/reuseport"
+	"github.com/valyala/tcplisten"
+)
+
+// ListenerOptions exposes the tcplisten socket options for the listener
+// built by ListenAndServe and ListenAndServeTLS.
+type ListenerOptions struct {
+	// ReusePort enables SO_REUSEPORT, allowing several processes to bind
+	// to the same address for kernel-level load balancing.
+	ReusePort bool
+
+	// DeferAccept enables TCP_DEFER_ACCEPT, reducing thundering-herd wakeups
+	// on accept by only waking up the server once data has arrived.
+	DeferAccept bool
+
+	// FastOpen enables TCP_FASTOPEN, allowing clients to send data in the
+	// handshake SYN packet for faster connection setup.
+	FastOpen bool
+
+	// Backlog is the maximum number of pending TCP connections the socket
+	// will queue. tcplisten's default is used if zero.
+	Backlog int
+}
+
+func (o ListenerOptions) enabled() bool {
+	return o.ReusePort || o.DeferAccept || o.FastOpen
+}
+
+// newListener builds the listener used by ListenAndServeTLS and ListenAndServe,
+// honouring Config.ListenerOptions, Config.Reuseport and Config.Network so it
+// keeps working when running under prefork (each child builds its own listener).
+func (s *Atreugo) newListener() (net.Listener, error) {
+	if s.cfg.ListenerOptions.enabled() {
+		cfg := tcplisten.Config{
+			ReusePort:   s.cfg.ListenerOptions.ReusePort,
+			DeferAccept: s.cfg.ListenerOptions.DeferAccept,
+			FastOpen:    s.cfg.ListenerOptions.FastOpen,
+			Backlog:     s.cfg.ListenerOptions.Backlog,
+		}
+
+		return cfg.NewListener(s.cfg.Network, s.cfg.Addr)
+	}
+
+	if s.cfg.Reuseport {
+		return reuseport.Listen(s.cfg.Network, s.cfg.Addr)
+	}
+
+	return net.Listen(s.cfg.Network, s.cfg.Addr)
+}
@@ -0,0 +1,175 @@
+package atreugo
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Logger is used for logging messages at different levels.
+//
+// With returns a child Logger that carries fields alongside every message it
+// logs afterwards, so call sites don't need to repeat request/connection
+// context on every call.
+type Logger interface {
+	PrintLogger
+
+	Debug(args ...interface{})
+	Debugf(format string, args ...interface{})
+	Info(args ...interface{})
+	Infof(format string, args ...interface{})
+	Warn(args ...interface{})
+	Warnf(format string, args ...interface{})
+	Error(args ...interface{})
+	Errorf(format string, args ...interface{})
+
+	With(fields ...interface{}) Logger
+}
+
+// NewPrintLoggerAdapter wraps an existing PrintLogger so it can be used
+// wherever a Logger is expected. All levels are routed through pl.Print,
+// so level information is lost; fields passed to With are
+// prefixed to every logged message instead of being kept structured.
+func NewPrintLoggerAdapter(pl PrintLogger) Logger {
+	return &printLoggerAdapter{pl: pl}
+}
+
+type printLoggerAdapter struct {
+	pl     PrintLogger
+	fields []interface{}
+}
+
+func (l *printLoggerAdapter) Print(v ...interface{}) {
+	l.pl.Print(append(append([]interface{}{}, l.fields...), v...)...)
+}
+
+func (l *printLoggerAdapter) Printf(format string, args ...interface{}) {
+	l.Print(fmt.Sprintf(format, args...))
+}
+
+func (l *printLoggerAdapter) log(level string, args ...interface{}) {
+	l.pl.Print(append([]interface{}{level}, append(l.fields, args...)...)...)
+}
+
+func (l *printLoggerAdapter) logf(level, format string, args ...interface{}) {
+	l.log(level, fmt.Sprintf(format, args...))
+}
+
+func (l *printLoggerAdapter) Debug(args ...interface{}) {
+	l.log("DEBUG", args...)
+}
+
+func (l *printLoggerAdapter) Debugf(format string, args ...interface{}) {
+	l.logf("DEBUG", format, args...)
+}
+
+func (l *printLoggerAdapter) Info(args ...interface{}) {
+	l.log("INFO", args...)
+}
+
+func (l *printLoggerAdapter) Infof(format string, args ...interface{}) {
+	l.logf("INFO", format, args...)
+}
+
+func (l *printLoggerAdapter) Warn(args ...interface{}) {
+	l.log("WARN", args...)
+}
+
+func (l *printLoggerAdapter) Warnf(format string, args ...interface{}) {
+	l.logf("WARN", format, args...)
+}
+
+func (l *printLoggerAdapter) Error(args ...interface{}) {
+	l.log("ERROR", args...)
+}
+
+func (l *printLoggerAdapter) Errorf(format string, args ...interface{}) {
+	l.logf("ERROR", format, args...)
+}
+
+func (l *printLoggerAdapter) With(fields ...interface{}) Logger {
+	return &printLoggerAdapter{
+		pl:     l.pl,
+		fields: append(append([]interface{}{}, l.fields...), fields...),
+	}
+}
+
+// fallbackLogger is returned by Logger() when no request-scoped Logger was
+// ever attached to the underlying *fasthttp.RequestCtx, so a misrouted or
+// hand-built RequestCtx never turns a Logger() call into a nil-interface panic.
+var fallbackLogger = defaultLogger()
+
+// defaultLogger is used when Config.Logger is not set.
+func defaultLogger() Logger {
+	return NewPrintLoggerAdapter(log.New(os.Stderr, "", log.LstdFlags))
+}
+
+// resolveLogger returns v, or fallbackLogger if v is nil. A pre-existing
+// PrintLogger implementation is not accepted here: it must be wrapped
+// explicitly with NewPrintLoggerAdapter and passed through Config.Logger.
+func resolveLogger(v Logger) Logger {
+	if v == nil {
+		return fallbackLogger
+	}
+
+	return v
+}
+
+// loggerUserValueKey is the UserValue key Atreugo.Handler attaches the
+// request-scoped Logger under. A dedicated, unexported key type is used so
+// it can never collide with a UserValue key set by user code.
+type loggerUserValueKey struct{}
+
+// Logger returns the request-scoped Logger, pre-populated with request-id,
+// remote-addr, method, path and connection sequence number.
+//
+// It is attached by Atreugo.Handler through ctx.SetUserValue before
+// middlewares and the view run, so it is always safe to call from inside a
+// View, Middleware, ErrorView or PanicView — including ones that build their
+// own *RequestCtx wrapper around the same underlying *fasthttp.RequestCtx,
+// since UserValue lives on the shared fasthttp.RequestCtx rather than on any
+// particular wrapper.
+func (ctx *RequestCtx) Logger() Logger {
+	if l, ok := ctx.UserValue(loggerUserValueKey{}).(Logger); ok {
+		return l
+	}
+
+	return fallbackLogger
+}
+
+// setLogger attaches logger to ctx as its request-scoped Logger, so that any
+// *RequestCtx built later around the same underlying *fasthttp.RequestCtx
+// observes it through Logger().
+func setLogger(ctx *fasthttp.RequestCtx, logger Logger) {
+	ctx.SetUserValue(loggerUserValueKey{}, logger)
+}
+
+// requestLogger builds the per-request Logger attached to a RequestCtx,
+// derived from the server's base logger.
+func requestLogger(base Logger, ctx *RequestCtx) Logger {
+	return base.With(
+		"request_id", ctx.ID(),
+		"remote_addr", ctx.RemoteAddr().String(),
+		"method", string(ctx.Method()),
+		"path", string(ctx.Path()),
+		"conn_seq", ctx.ConnRequestNum(),
+	)
+}
+
+// defaultErrorView is used when Config.ErrorView is not set. It logs the
+// error through the request-scoped Logger before writing the error message
+// as the response, with statusCode as its status.
+func defaultErrorView(ctx *RequestCtx, err error, statusCode int) {
+	ctx.Logger().Error(fmt.Sprintf("view error: %s", err))
+	ctx.Error(err.Error(), statusCode)
+}
+
+// defaultPanicView is used when Config.PanicView is not set. It logs the
+// recovered panic through the request-scoped Logger before writing a 500
+// response, so the server never crashes because of an unrecovered panic.
+func defaultPanicView(ctx *RequestCtx, p interface{}) {
+	ctx.Logger().Error(fmt.Sprintf("recovered from panic: %v", p))
+	ctx.Error("Internal Server Error", fasthttp.StatusInternalServerError)
+}
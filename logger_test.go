@@ -0,0 +1,44 @@
+package atreugo
+
+import (
+	"testing"
+
+	fastrouter "github.com/fasthttp/router"
+	"github.com/valyala/fasthttp"
+)
+
+// TestHandlerPropagatesRequestLogger asserts that the Logger attached by
+// Atreugo.Handler is observable from a *RequestCtx wrapper built further
+// down the routing path (mimicking a registered view), with the
+// request-scoped fields populated rather than the fallback.
+func TestHandlerPropagatesRequestLogger(t *testing.T) {
+	fr := fastrouter.New()
+
+	var got Logger
+
+	fr.GET("/greet", func(ctx *fasthttp.RequestCtx) {
+		// A registered view receives a freshly built *RequestCtx wrapper,
+		// independent of the one Atreugo.Handler constructed.
+		actx := &RequestCtx{RequestCtx: ctx}
+		got = actx.Logger()
+	})
+
+	s := &Atreugo{
+		cfg:    Config{},
+		Router: &Router{router: fr, cfg: &routerConfig{}},
+	}
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod("GET")
+	ctx.Request.SetRequestURI("/greet")
+
+	s.Handler(ctx)
+
+	if got == nil {
+		t.Fatal("Logger() returned nil inside the registered view")
+	}
+
+	if got == fallbackLogger {
+		t.Fatal("Logger() returned fallbackLogger instead of the request-scoped logger")
+	}
+}
@@ -0,0 +1,461 @@
+package atreugo
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// RateLimiterKeyFunc extracts the key a RateLimiter should bucket a request
+// under. RateLimiterRemoteIPKey is used by default, keying on ctx.RemoteIP().
+type RateLimiterKeyFunc func(ctx *RequestCtx) string
+
+// RateLimiterRemoteIPKey is the default RateLimiterKeyFunc, keying requests
+// by their remote IP address.
+func RateLimiterRemoteIPKey(ctx *RequestCtx) string {
+	return ctx.RemoteIP().String()
+}
+
+// RateLimiterStore tracks the per-key state a RateLimiter needs: a token
+// bucket for the requests/sec + burst limit, and a counter for the
+// concurrent in-flight requests limit.
+//
+// The bundled NewMemoryRateLimiterStore is safe under Config.Prefork, but its
+// state is per-process: each prefork child enforces its own independent
+// limits. A Redis-backed store should be used instead to share state across
+// processes or machines.
+type RateLimiterStore interface {
+	// Allow reports whether a request under key may proceed according to
+	// the requests/sec rate and burst size, consuming a token if so.
+	Allow(key string, ratePerSec float64, burst int) bool
+
+	// Acquire reports whether a request under key may proceed according to
+	// the maximum number of concurrent in-flight requests, reserving a slot
+	// if so. The caller must call Release exactly once when the request
+	// finishes, iff Acquire returned true.
+	Acquire(key string, maxInFlight int) bool
+
+	// Release frees the in-flight slot reserved by a successful Acquire.
+	Release(key string)
+
+	// Close stops any background goroutines the store owns (such as an idle
+	// sweep). It is safe to call more than once. Callers that create a store
+	// and own its lifetime (e.g. via Config.GracefulShutdown, or a test
+	// harness) should call Close when done with it.
+	Close() error
+}
+
+// RateLimiterConfig configures a RateLimiter middleware.
+type RateLimiterConfig struct {
+	// Rate is the sustained number of requests per second allowed per key.
+	// No rate limiting is applied if zero.
+	Rate float64
+
+	// Burst is the maximum number of requests a key may burst above Rate
+	// before being throttled. Defaults to 1 if zero and Rate is set.
+	Burst int
+
+	// MaxInFlight is the maximum number of concurrent in-flight requests
+	// allowed per key. No concurrency limiting is applied if zero.
+	MaxInFlight int
+
+	// KeyFunc extracts the bucketing key from the request.
+	// RateLimiterRemoteIPKey is used if nil.
+	KeyFunc RateLimiterKeyFunc
+
+	// LimitExceededView is called when a request is rejected, either by the
+	// rate or the in-flight limit. A default view returning 429 with a
+	// Retry-After header is used if nil.
+	LimitExceededView View
+
+	// Store is the backend tracking rate/in-flight state per key.
+	// NewMemoryRateLimiterStore() is used if nil.
+	Store RateLimiterStore
+}
+
+// RateLimiter returns a Middleware enforcing per-key request rate and
+// concurrency limits, complementing fasthttp's connection-level anti-DoS
+// knobs (such as Config.MaxConnsPerIP) with request-level limiting.
+//
+// It can be registered globally with UseBefore, or per Path.
+func RateLimiter(cfg RateLimiterConfig) Middleware {
+	if cfg.KeyFunc == nil {
+		cfg.KeyFunc = RateLimiterRemoteIPKey
+	}
+
+	if cfg.LimitExceededView == nil {
+		cfg.LimitExceededView = defaultLimitExceededView
+	}
+
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryRateLimiterStore()
+	}
+
+	if cfg.Rate > 0 && cfg.Burst <= 0 {
+		cfg.Burst = 1
+	}
+
+	return func(ctx *RequestCtx) error {
+		key := cfg.KeyFunc(ctx)
+
+		// Acquire is checked before Allow so a request rejected by the
+		// concurrency cap never consumes a rate-limit token: the two limits
+		// are independent, and a caller throttled by one shouldn't also pay
+		// for the other.
+		if cfg.MaxInFlight > 0 {
+			if !cfg.Store.Acquire(key, cfg.MaxInFlight) {
+				return cfg.LimitExceededView(ctx)
+			}
+
+			defer cfg.Store.Release(key)
+		}
+
+		if cfg.Rate > 0 && !cfg.Store.Allow(key, cfg.Rate, cfg.Burst) {
+			return cfg.LimitExceededView(ctx)
+		}
+
+		return ctx.Next()
+	}
+}
+
+func defaultLimitExceededView(ctx *RequestCtx) error {
+	ctx.Response.Header.Set("Retry-After", "1")
+	ctx.Error("Too Many Requests", 429)
+
+	return nil
+}
+
+const rateLimiterShards = 32
+
+const (
+	// defaultRateLimiterIdleTTL is how long a key may go unseen before its
+	// state is evicted from the in-memory store.
+	defaultRateLimiterIdleTTL = 5 * time.Minute
+
+	// defaultRateLimiterSweepInterval is how often each shard is swept for
+	// idle keys.
+	defaultRateLimiterSweepInterval = time.Minute
+
+	// maxKeysPerShard bounds how many distinct keys a single shard will
+	// track between sweeps, so a burst of unique keys (e.g. a spoofed,
+	// attacker-controlled RateLimiterKeyFunc) can't grow the store without
+	// bound even before the idle TTL kicks in.
+	maxKeysPerShard = 20000
+)
+
+// NewMemoryRateLimiterStore returns a RateLimiterStore backed by an
+// in-memory sharded map.
+//
+// Keys idle for longer than defaultRateLimiterIdleTTL are evicted by a
+// background sweep every defaultRateLimiterSweepInterval, and each shard is
+// additionally capped at maxKeysPerShard entries, so a key space driven by
+// attacker-controlled input (e.g. KeyFunc keying on X-Forwarded-For) can't
+// grow the store without bound.
+//
+// WARNING: under Config.Prefork each child process gets its own store, so
+// the effective limit per key is multiplied by the number of prefork
+// children. Use a shared backend (e.g. Redis) if a single global limit is
+// required.
+//
+// The returned store runs a background goroutine for the idle sweep; call
+// Close when done with it to stop that goroutine.
+func NewMemoryRateLimiterStore() RateLimiterStore {
+	s := &memoryRateLimiterStore{
+		stopCh: make(chan struct{}),
+	}
+
+	for i := range s.shards {
+		s.shards[i] = &rateLimiterShard{
+			entries: make(map[string]*rateLimiterEntry),
+			idle:    list.New(),
+		}
+	}
+
+	s.sweepWG.Add(1)
+
+	go s.sweepLoop(defaultRateLimiterIdleTTL, defaultRateLimiterSweepInterval)
+
+	return s
+}
+
+type tokenBucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+// rateLimiterEntry is the per-key state tracked by a shard. idleElem is the
+// entry's node in shard.idle while it has no request in flight, and nil
+// while a request is in flight (in-flight keys aren't eviction candidates).
+type rateLimiterEntry struct {
+	key      string
+	bucket   *tokenBucket
+	inFlight int
+	lastSeen time.Time
+	idleElem *list.Element
+}
+
+// rateLimiterShard holds the per-key state for one of a
+// memoryRateLimiterStore's shards. entries gives O(1) lookup by key; idle is
+// a doubly-linked list of the entries with no request in flight, ordered
+// most-recently-seen first, so the least-recently-seen evictable entry is
+// always at the back in O(1) — admitting a new key into a full shard never
+// scans the whole map.
+type rateLimiterShard struct {
+	mu      sync.Mutex
+	entries map[string]*rateLimiterEntry
+	idle    *list.List
+}
+
+// touchLocked records key as seen now, moving it to the front of the idle
+// list if it isn't currently in flight. The caller must hold shard.mu.
+func (shard *rateLimiterShard) touchLocked(e *rateLimiterEntry, now time.Time) {
+	e.lastSeen = now
+
+	if e.idleElem != nil {
+		shard.idle.MoveToFront(e.idleElem)
+	}
+}
+
+// markInFlightLocked removes e from the idle list while it has a request in
+// flight, so it can't be picked for eviction. The caller must hold shard.mu.
+func (shard *rateLimiterShard) markInFlightLocked(e *rateLimiterEntry) {
+	if e.idleElem != nil {
+		shard.idle.Remove(e.idleElem)
+		e.idleElem = nil
+	}
+}
+
+// markIdleLocked returns e to the idle list once it has no request left in
+// flight. The caller must hold shard.mu.
+func (shard *rateLimiterShard) markIdleLocked(e *rateLimiterEntry) {
+	if e.idleElem == nil {
+		e.idleElem = shard.idle.PushFront(e)
+	}
+}
+
+func (shard *rateLimiterShard) deleteLocked(e *rateLimiterEntry) {
+	if e.idleElem != nil {
+		shard.idle.Remove(e.idleElem)
+	}
+
+	delete(shard.entries, e.key)
+}
+
+// evictIdleLocked removes entries last seen more than idleTTL ago. Entries
+// with a request in flight are never in the idle list, so they're
+// untouched. The idle list is ordered most-recently-seen first, so this
+// walks from the back and stops at the first entry that's still fresh. The
+// caller must hold shard.mu.
+func (shard *rateLimiterShard) evictIdleLocked(idleTTL time.Duration) {
+	now := time.Now()
+
+	for elem := shard.idle.Back(); elem != nil; {
+		e, _ := elem.Value.(*rateLimiterEntry)
+		if now.Sub(e.lastSeen) <= idleTTL {
+			break
+		}
+
+		prev := elem.Prev()
+		shard.deleteLocked(e)
+		elem = prev
+	}
+}
+
+// admitNewKeyLocked reports whether shard may start tracking one more key,
+// evicting to make room if necessary. It is the single choke point new keys
+// go through, so maxKeysPerShard is an actual ceiling rather than a number
+// that's merely consulted.
+//
+// It first evicts anything idle longer than idleTTL. If that alone doesn't
+// free a slot, it falls back to evicting the single least-recently-seen
+// entry with no request in flight — the back of the idle list, an O(1)
+// lookup. If every tracked key currently has a request in flight, the shard
+// is genuinely at capacity and the new key is refused rather than admitted
+// unbounded. The caller must hold shard.mu.
+func (shard *rateLimiterShard) admitNewKeyLocked(idleTTL time.Duration) bool {
+	if len(shard.entries) < maxKeysPerShard {
+		return true
+	}
+
+	shard.evictIdleLocked(idleTTL)
+
+	if len(shard.entries) < maxKeysPerShard {
+		return true
+	}
+
+	back := shard.idle.Back()
+	if back == nil {
+		return false
+	}
+
+	e, _ := back.Value.(*rateLimiterEntry)
+	shard.deleteLocked(e)
+
+	return true
+}
+
+type memoryRateLimiterStore struct {
+	shards   [rateLimiterShards]*rateLimiterShard
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	sweepWG  sync.WaitGroup
+}
+
+func (s *memoryRateLimiterStore) shardFor(key string) *rateLimiterShard {
+	h := fnv32(key)
+
+	return s.shards[h%rateLimiterShards]
+}
+
+func (s *memoryRateLimiterStore) sweepLoop(idleTTL, interval time.Duration) {
+	defer s.sweepWG.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			for _, shard := range s.shards {
+				shard.mu.Lock()
+				shard.evictIdleLocked(idleTTL)
+				shard.mu.Unlock()
+			}
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// Close stops the background idle sweep. It is safe to call more than once
+// and blocks until the sweep goroutine has exited.
+func (s *memoryRateLimiterStore) Close() error {
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+
+	s.sweepWG.Wait()
+
+	return nil
+}
+
+func (s *memoryRateLimiterStore) Allow(key string, ratePerSec float64, burst int) bool {
+	shard := s.shardFor(key)
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	now := time.Now()
+
+	e, ok := shard.entries[key]
+	if !ok {
+		if !shard.admitNewKeyLocked(defaultRateLimiterIdleTTL) {
+			return false
+		}
+
+		e = &rateLimiterEntry{
+			key:      key,
+			bucket:   &tokenBucket{tokens: float64(burst) - 1, lastRefill: now},
+			lastSeen: now,
+		}
+		e.idleElem = shard.idle.PushFront(e)
+		shard.entries[key] = e
+
+		return true
+	}
+
+	shard.touchLocked(e, now)
+
+	if e.bucket == nil {
+		// The entry already exists (created by Acquire for the in-flight
+		// cap) but has never been through Allow before.
+		e.bucket = &tokenBucket{tokens: float64(burst) - 1, lastRefill: now}
+
+		return true
+	}
+
+	b := e.bucket
+	elapsed := now.Sub(b.lastRefill).Seconds()
+	b.lastRefill = now
+
+	b.tokens += elapsed * ratePerSec
+	if b.tokens > float64(burst) {
+		b.tokens = float64(burst)
+	}
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+
+	return true
+}
+
+func (s *memoryRateLimiterStore) Acquire(key string, maxInFlight int) bool {
+	shard := s.shardFor(key)
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	now := time.Now()
+
+	e, ok := shard.entries[key]
+	if !ok {
+		if !shard.admitNewKeyLocked(defaultRateLimiterIdleTTL) {
+			return false
+		}
+
+		e = &rateLimiterEntry{key: key, lastSeen: now}
+		e.idleElem = shard.idle.PushFront(e)
+		shard.entries[key] = e
+	}
+
+	if e.inFlight >= maxInFlight {
+		return false
+	}
+
+	e.inFlight++
+	shard.markInFlightLocked(e)
+	shard.touchLocked(e, now)
+
+	return true
+}
+
+func (s *memoryRateLimiterStore) Release(key string) {
+	shard := s.shardFor(key)
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	e, ok := shard.entries[key]
+	if !ok {
+		return
+	}
+
+	if e.inFlight > 0 {
+		e.inFlight--
+	}
+
+	shard.touchLocked(e, time.Now())
+
+	if e.inFlight == 0 {
+		shard.markIdleLocked(e)
+	}
+}
+
+// fnv32 is a small, dependency-free FNV-1a hash used to pick a shard for key.
+func fnv32(key string) uint32 {
+	const (
+		offset32 = 2166136261
+		prime32  = 16777619
+	)
+
+	h := uint32(offset32)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= prime32
+	}
+
+	return h
+}
@@ -0,0 +1,178 @@
+package atreugo
+
+import (
+	"container/list"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// TestMemoryRateLimiterStoreTokenBucketRefill asserts the token-bucket math:
+// a burst is exhausted immediately, throttled before it refills, and allowed
+// again once enough time has passed to refill at least one token.
+func TestMemoryRateLimiterStoreTokenBucketRefill(t *testing.T) {
+	store := NewMemoryRateLimiterStore()
+	defer store.Close()
+
+	const key = "refill"
+
+	if !store.Allow(key, 1000, 1) {
+		t.Fatal("expected the first request to be allowed")
+	}
+
+	if store.Allow(key, 1000, 1) {
+		t.Fatal("expected the second request to be throttled before the bucket refills")
+	}
+
+	time.Sleep(5 * time.Millisecond)
+
+	if !store.Allow(key, 1000, 1) {
+		t.Fatal("expected a request to be allowed again once the bucket has refilled")
+	}
+}
+
+// TestMemoryRateLimiterStoreAcquireRelease asserts the concurrency cap: a
+// key may not exceed maxInFlight concurrent Acquires, and Release frees a
+// slot for a subsequent Acquire.
+func TestMemoryRateLimiterStoreAcquireRelease(t *testing.T) {
+	store := NewMemoryRateLimiterStore()
+	defer store.Close()
+
+	const key = "inflight"
+
+	if !store.Acquire(key, 1) {
+		t.Fatal("expected the first Acquire to succeed")
+	}
+
+	if store.Acquire(key, 1) {
+		t.Fatal("expected a second concurrent Acquire to be rejected")
+	}
+
+	store.Release(key)
+
+	if !store.Acquire(key, 1) {
+		t.Fatal("expected Acquire to succeed again after Release")
+	}
+}
+
+// TestMemoryRateLimiterStoreAllowAfterAcquireOnly asserts that Allow works
+// for a key whose entry was first created by Acquire (no Rate configured
+// for that call), rather than panicking on a nil token bucket.
+func TestMemoryRateLimiterStoreAllowAfterAcquireOnly(t *testing.T) {
+	store := NewMemoryRateLimiterStore()
+	defer store.Close()
+
+	const key = "acquire-then-allow"
+
+	if !store.Acquire(key, 1) {
+		t.Fatal("expected Acquire to succeed")
+	}
+
+	if !store.Allow(key, 1000, 1) {
+		t.Fatal("expected Allow to succeed for a key only seen via Acquire so far")
+	}
+
+	store.Release(key)
+}
+
+// TestRateLimiterAcquireBeforeAllow asserts that a request rejected by the
+// concurrency cap never reaches Allow, so it can't consume a rate-limit
+// token it never gets to use.
+func TestRateLimiterAcquireBeforeAllow(t *testing.T) {
+	store := &spyRateLimiterStore{acquireResult: false}
+
+	mw := RateLimiter(RateLimiterConfig{
+		Rate:        1,
+		MaxInFlight: 1,
+		Store:       store,
+	})
+
+	ctx := &RequestCtx{RequestCtx: &fasthttp.RequestCtx{}}
+
+	if err := mw(ctx); err != nil {
+		t.Fatalf("middleware returned an unexpected error: %v", err)
+	}
+
+	if store.allowCalled {
+		t.Fatal("Allow must not be called once Acquire has rejected the request")
+	}
+}
+
+type spyRateLimiterStore struct {
+	acquireResult bool
+	allowCalled   bool
+}
+
+func (s *spyRateLimiterStore) Allow(key string, ratePerSec float64, burst int) bool {
+	s.allowCalled = true
+
+	return true
+}
+
+func (s *spyRateLimiterStore) Acquire(key string, maxInFlight int) bool {
+	return s.acquireResult
+}
+
+func (s *spyRateLimiterStore) Release(key string) {}
+
+func (s *spyRateLimiterStore) Close() error { return nil }
+
+// TestRateLimiterShardAdmitNewKeyEvictsLRU asserts that once a shard is at
+// maxKeysPerShard, admitting one more key evicts the single
+// least-recently-seen entry rather than refusing every further key.
+func TestRateLimiterShardAdmitNewKeyEvictsLRU(t *testing.T) {
+	shard := &rateLimiterShard{
+		entries: make(map[string]*rateLimiterEntry),
+		idle:    list.New(),
+	}
+
+	for i := 0; i < maxKeysPerShard; i++ {
+		key := fmt.Sprintf("key-%d", i)
+
+		if !shard.admitNewKeyLocked(time.Hour) {
+			t.Fatalf("admitNewKeyLocked rejected key %d while under capacity", i)
+		}
+
+		e := &rateLimiterEntry{key: key, lastSeen: time.Now()}
+		e.idleElem = shard.idle.PushFront(e)
+		shard.entries[key] = e
+	}
+
+	if len(shard.entries) != maxKeysPerShard {
+		t.Fatalf("expected %d entries, got %d", maxKeysPerShard, len(shard.entries))
+	}
+
+	if !shard.admitNewKeyLocked(time.Hour) {
+		t.Fatal("expected admitNewKeyLocked to evict the LRU entry to make room")
+	}
+
+	if len(shard.entries) != maxKeysPerShard-1 {
+		t.Fatalf("expected exactly one entry to be evicted, leaving %d, got %d", maxKeysPerShard-1, len(shard.entries))
+	}
+
+	if _, ok := shard.entries["key-0"]; ok {
+		t.Fatal("expected the least-recently-seen entry to have been evicted")
+	}
+}
+
+// TestRateLimiterShardAdmitNewKeyAllInFlight asserts that a shard at
+// capacity with every entry in flight refuses a new key instead of
+// evicting one that's actively in use.
+func TestRateLimiterShardAdmitNewKeyAllInFlight(t *testing.T) {
+	shard := &rateLimiterShard{
+		entries: make(map[string]*rateLimiterEntry),
+		idle:    list.New(),
+	}
+
+	for i := 0; i < maxKeysPerShard; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		e := &rateLimiterEntry{key: key, inFlight: 1, lastSeen: time.Now()}
+		shard.entries[key] = e
+	}
+
+	if shard.admitNewKeyLocked(time.Hour) {
+		t.Fatal("expected admitNewKeyLocked to refuse a new key when every entry is in flight")
+	}
+}
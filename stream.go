@@ -0,0 +1,115 @@
+package atreugo
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/valyala/fasthttp"
+)
+
+// DefaultMaxChunkSize is the buffer size used to read the request body
+// in StreamView handlers when no MaxChunkSize is given.
+const DefaultMaxChunkSize = 4096
+
+// ErrBodyTooLarge is returned by the io.Reader passed to a StreamHandler
+// once the request body has exceeded Config.MaxRequestBodySize. A handler
+// seeing it should treat the body as incomplete/invalid rather than as a
+// normal EOF.
+var ErrBodyTooLarge = errors.New("atreugo: request body exceeds MaxRequestBodySize")
+
+// StreamHandler processes an incoming request body incrementally through r,
+// instead of through ctx.PostBody().
+type StreamHandler func(ctx *RequestCtx, r io.Reader) error
+
+// StreamView registers a view that consumes the request body as a stream
+// through fn, reading it in bounded chunks instead of buffering the whole
+// body in memory.
+//
+// Registering a StreamView on any Path enables Config.StreamRequestBody on
+// the engine for the whole server, and fn is invoked as soon as the headers
+// and the first chunk of the body have arrived. The remaining body is read
+// in chunks of up to maxChunkSize bytes (DefaultMaxChunkSize if omitted),
+// never exceeding Config.MaxRequestBodySize in total. Errors returned by fn
+// go through ErrorView and panics through PanicView, like any other view,
+// and after-middlewares run as soon as fn returns: StreamView does not drain
+// the part of the body fn left unread, and the reader has no Close method.
+func (p *Path) StreamView(fn StreamHandler, maxChunkSize ...int) *Path {
+	chunkSize := DefaultMaxChunkSize
+	if len(maxChunkSize) > 0 && maxChunkSize[0] > 0 {
+		chunkSize = maxChunkSize[0]
+	}
+
+	p.router.cfg.streamRequestBody = true
+
+	p.view = func(ctx *RequestCtx) error {
+		return fn(ctx, newBodyStream(ctx, p.router.cfg.maxRequestBodySize, chunkSize))
+	}
+
+	return p
+}
+
+// newBodyStream returns the reader StreamView handlers read the request
+// body from, buffering it through chunkSize reads and capping it at
+// maxRequestBodySize, falling back to fasthttp's own default cap when unset.
+// Reading past the cap returns ErrBodyTooLarge instead of a silent EOF, so a
+// handler can't mistake a truncated body for a complete one.
+func newBodyStream(ctx *RequestCtx, maxRequestBodySize, chunkSize int) io.Reader {
+	if maxRequestBodySize <= 0 {
+		maxRequestBodySize = fasthttp.DefaultMaxRequestBodySize
+	}
+
+	r := ctx.RequestBodyStream()
+	if r == nil {
+		// Body streaming wasn't negotiated for this request (e.g. fully
+		// buffered already because it fit in one read), fall back to the
+		// body fasthttp already read.
+		r = bytes.NewReader(ctx.PostBody())
+	}
+
+	return bufio.NewReaderSize(&capReader{r: r, remaining: int64(maxRequestBodySize), chunk: chunkSize}, chunkSize)
+}
+
+// capReader wraps r, erroring with ErrBodyTooLarge as soon as more than
+// remaining bytes are read from it instead of silently truncating like
+// io.LimitReader does. Each Read returns at most chunk bytes, even when the
+// caller's buffer is larger.
+type capReader struct {
+	r         io.Reader
+	remaining int64
+	chunk     int
+}
+
+func (c *capReader) Read(p []byte) (int, error) {
+	if c.remaining <= 0 {
+		// The cap was already hit; any further byte means the body is
+		// actually larger than the limit rather than having ended exactly
+		// at it.
+		var probe [1]byte
+
+		n, err := c.r.Read(probe[:])
+		if n > 0 {
+			return 0, ErrBodyTooLarge
+		}
+
+		if err == nil || errors.Is(err, io.EOF) {
+			return 0, io.EOF
+		}
+
+		return 0, err
+	}
+
+	if c.chunk > 0 && len(p) > c.chunk {
+		p = p[:c.chunk]
+	}
+
+	if int64(len(p)) > c.remaining {
+		p = p[:c.remaining]
+	}
+
+	n, err := c.r.Read(p)
+	c.remaining -= int64(n)
+
+	return n, err
+}
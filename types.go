@@ -11,8 +11,11 @@ import (
 	"github.com/valyala/fasthttp"
 )
 
-// Logger is used for logging messages.
-type Logger interface {
+// PrintLogger is used for logging unstructured messages.
+//
+// It is kept for backwards compatibility: existing PrintLogger implementations
+// can still be plugged in as Config.Logger through NewPrintLoggerAdapter.
+type PrintLogger interface {
 	Print(v ...interface{})
 	Printf(format string, args ...interface{})
 }
@@ -31,6 +34,7 @@ type Atreugo struct {
 	cfg    Config
 
 	virtualHosts map[string]fasthttp.RequestHandler
+	regexHosts   []regexHost
 
 	*Router
 }
@@ -57,10 +61,20 @@ type Config struct { // nolint:maligned
 	// instead.
 	TLSConfig *tls.Config
 
+	// HTTP2 configures HTTP/2 support.
+	//
+	// HTTP/2 is only negotiated over a TLS listener, unless ServeH2C is used
+	// to serve cleartext HTTP/2 (h2c) for clients behind a TLS-terminating proxy.
+	HTTP2 HTTP2Config
+
 	// Server name for sending in response headers. (default: Atreugo)
 	Name string
 
-	// Logger (optional)
+	// Logger (optional).
+	//
+	// defaultLogger() is used if nil. A pre-existing PrintLogger
+	// implementation can still be plugged in by wrapping it explicitly with
+	// NewPrintLoggerAdapter.
 	Logger Logger
 
 	// Log debug traces
@@ -93,6 +107,14 @@ type Config struct { // nolint:maligned
 	// See: https://www.nginx.com/blog/socket-sharding-nginx-release-1-9-1/.
 	Reuseport bool
 
+	// ListenerOptions builds the listener through github.com/valyala/tcplisten
+	// instead of the plain Reuseport path, exposing SO_REUSEPORT, TCP_DEFER_ACCEPT
+	// and TCP_FASTOPEN. Just supported on tcp4 and tcp6.
+	//
+	// If ListenerOptions.ReusePort, DeferAccept or FastOpen are set, it takes
+	// precedence over Reuseport.
+	ListenerOptions ListenerOptions
+
 	// Shutdown gracefully shuts down the server without interrupting any active connections.
 	// Shutdown works by first closing all open listeners and then waiting indefinitely for all connections
 	// to return to idle and then shut down.
@@ -496,6 +518,14 @@ type routerConfig struct {
 
 	debug  bool
 	logger Logger
+
+	// maxRequestBodySize mirrors Config.MaxRequestBodySize and is used to cap
+	// reads from StreamView handlers, which bypass fasthttp's own body limit.
+	maxRequestBodySize int
+
+	// streamRequestBody is set to true as soon as one Path registers a
+	// StreamView, so the engine can have StreamRequestBody turned on for it.
+	streamRequestBody bool
 }
 
 // Router dispatchs requests to different
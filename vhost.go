@@ -0,0 +1,94 @@
+package atreugo
+
+import (
+	"net"
+	"regexp"
+	"strings"
+
+	fastrouter "github.com/fasthttp/router"
+	"github.com/valyala/fasthttp"
+)
+
+// regexHost pairs a compiled host pattern with the handler mounted for it.
+type regexHost struct {
+	pattern *regexp.Regexp
+	handler fasthttp.RequestHandler
+}
+
+// Host returns a new Router whose routes and middlewares are compiled and
+// mounted as the handler for requests whose Host header matches hostname
+// exactly.
+//
+// This lets a single Atreugo process serve several domains, each with its
+// own independent middleware stack, without hand-writing a dispatcher on
+// top of Config.HeaderReceived or similar hooks.
+func (s *Atreugo) Host(hostname string) *Router {
+	r := s.newVirtualRouter()
+
+	if s.virtualHosts == nil {
+		s.virtualHosts = make(map[string]fasthttp.RequestHandler)
+	}
+
+	s.virtualHosts[normalizeHost(hostname)] = r.router.Handler
+
+	return r
+}
+
+// HostRegex behaves like Host, but matches the request's Host header against
+// pattern as a regular expression instead of requiring an exact match, for
+// wildcard hosts such as `^.*\.api\.example\.com$`.
+//
+// Regex hosts are matched in the order they were registered, after exact
+// Host matches have been tried and failed.
+func (s *Atreugo) HostRegex(pattern string) *Router {
+	r := s.newVirtualRouter()
+
+	s.regexHosts = append(s.regexHosts, regexHost{
+		pattern: regexp.MustCompile(pattern),
+		handler: r.router.Handler,
+	})
+
+	return r
+}
+
+// newVirtualRouter builds a standalone Router sharing this server's
+// routerConfig (error handling, debug and logger settings), but with its
+// own fastrouter.Router so its routes don't leak into the main dispatcher.
+func (s *Atreugo) newVirtualRouter() *Router {
+	return &Router{
+		router: fastrouter.New(),
+		cfg:    s.Router.cfg,
+	}
+}
+
+// virtualHostHandler returns the handler mounted for ctx's Host header,
+// matching exact hosts first and then regex hosts, or false if ctx.Host()
+// doesn't match any registered virtual host and the main router should
+// handle it instead.
+func (s *Atreugo) virtualHostHandler(ctx *fasthttp.RequestCtx) (fasthttp.RequestHandler, bool) {
+	host := normalizeHost(string(ctx.Host()))
+
+	if h, ok := s.virtualHosts[host]; ok {
+		return h, true
+	}
+
+	for _, rh := range s.regexHosts {
+		if rh.pattern.MatchString(host) {
+			return rh.handler, true
+		}
+	}
+
+	return nil, false
+}
+
+// normalizeHost strips an optional ":port" suffix and lowercases host, so
+// registered and incoming hosts compare equal regardless of the port a
+// client connected on (e.g. "example.com:8443") or the header's case (e.g.
+// "Example.com").
+func normalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	return strings.ToLower(host)
+}
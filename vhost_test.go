@@ -0,0 +1,114 @@
+package atreugo
+
+import (
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+// tagRouter registers a GET / route on r that answers with tag, so that
+// handlerTag can tell which Router a handler returned by virtualHostHandler
+// belongs to.
+func tagRouter(r *Router, tag string) {
+	r.router.GET("/", func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(tag)
+	})
+}
+
+// handlerTag runs h on a GET / request and returns the body it answered
+// with. Method values such as r.router.Handler share one code pointer for
+// every receiver, so comparing them through reflect can't tell two Routers
+// apart.
+func handlerTag(h fasthttp.RequestHandler) string {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod("GET")
+	ctx.Request.SetRequestURI("/")
+
+	h(ctx)
+
+	return string(ctx.Response.Body())
+}
+
+// TestVirtualHostHandlerNormalizesHost asserts that an exact Host match is
+// found regardless of the incoming header's case or an explicit port, since
+// Host("example.com") is registered without either.
+func TestVirtualHostHandlerNormalizesHost(t *testing.T) {
+	s := &Atreugo{Router: &Router{cfg: &routerConfig{}}}
+
+	tagRouter(s.Host("Example.com"), "example")
+
+	hosts := []string{"example.com", "Example.com", "EXAMPLE.COM", "example.com:8443"}
+
+	for _, host := range hosts {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.Header.SetHost(host)
+
+		got, ok := s.virtualHostHandler(ctx)
+		if !ok {
+			t.Errorf("host %q: expected a match, got none", host)
+			continue
+		}
+
+		if tag := handlerTag(got); tag != "example" {
+			t.Errorf("host %q: matched the wrong handler, got %q", host, tag)
+		}
+	}
+}
+
+// TestVirtualHostHandlerExactBeforeRegex asserts that an exact Host match is
+// preferred over a HostRegex that would also match.
+func TestVirtualHostHandlerExactBeforeRegex(t *testing.T) {
+	s := &Atreugo{Router: &Router{cfg: &routerConfig{}}}
+
+	tagRouter(s.Host("api.example.com"), "exact")
+	tagRouter(s.HostRegex(`^.*\.example\.com$`), "regex")
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetHost("api.example.com")
+
+	got, ok := s.virtualHostHandler(ctx)
+	if !ok {
+		t.Fatal("expected a match")
+	}
+
+	if tag := handlerTag(got); tag != "exact" {
+		t.Fatalf("expected the exact Host match to win over the HostRegex match, got %q", tag)
+	}
+}
+
+// TestVirtualHostHandlerRegexFallback asserts that a HostRegex is matched,
+// normalized the same way as exact hosts, once no exact host matches.
+func TestVirtualHostHandlerRegexFallback(t *testing.T) {
+	s := &Atreugo{Router: &Router{cfg: &routerConfig{}}}
+
+	tagRouter(s.HostRegex(`^.*\.example\.com$`), "regex")
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetHost("Sub.Example.com:8443")
+
+	got, ok := s.virtualHostHandler(ctx)
+	if !ok {
+		t.Fatal("expected the regex host to match")
+	}
+
+	if tag := handlerTag(got); tag != "regex" {
+		t.Fatalf("matched the wrong handler, got %q", tag)
+	}
+}
+
+// TestVirtualHostHandlerNoMatch asserts that a Host header matching neither
+// an exact nor a regex host reports no match, so the caller falls back to
+// the main router.
+func TestVirtualHostHandlerNoMatch(t *testing.T) {
+	s := &Atreugo{Router: &Router{cfg: &routerConfig{}}}
+
+	s.Host("example.com")
+	s.HostRegex(`^.*\.example\.com$`)
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetHost("unrelated.org")
+
+	if _, ok := s.virtualHostHandler(ctx); ok {
+		t.Fatal("expected no match for an unrelated host")
+	}
+}
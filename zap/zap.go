@@ -0,0 +1,61 @@
+// Package zap provides an atreugo.Logger implementation backed by
+// go.uber.org/zap, for use as Config.Logger.
+package zap
+
+import (
+	"github.com/savsgio/atreugo/v11"
+	"go.uber.org/zap"
+)
+
+type logger struct {
+	sugar *zap.SugaredLogger
+}
+
+// New wraps sugar as an atreugo.Logger.
+func New(sugar *zap.SugaredLogger) atreugo.Logger {
+	return &logger{sugar: sugar}
+}
+
+func (l *logger) Print(v ...interface{}) {
+	l.sugar.Info(v...)
+}
+
+func (l *logger) Printf(format string, args ...interface{}) {
+	l.sugar.Infof(format, args...)
+}
+
+func (l *logger) Debug(args ...interface{}) {
+	l.sugar.Debug(args...)
+}
+
+func (l *logger) Debugf(format string, args ...interface{}) {
+	l.sugar.Debugf(format, args...)
+}
+
+func (l *logger) Info(args ...interface{}) {
+	l.sugar.Info(args...)
+}
+
+func (l *logger) Infof(format string, args ...interface{}) {
+	l.sugar.Infof(format, args...)
+}
+
+func (l *logger) Warn(args ...interface{}) {
+	l.sugar.Warn(args...)
+}
+
+func (l *logger) Warnf(format string, args ...interface{}) {
+	l.sugar.Warnf(format, args...)
+}
+
+func (l *logger) Error(args ...interface{}) {
+	l.sugar.Error(args...)
+}
+
+func (l *logger) Errorf(format string, args ...interface{}) {
+	l.sugar.Errorf(format, args...)
+}
+
+func (l *logger) With(fields ...interface{}) atreugo.Logger {
+	return &logger{sugar: l.sugar.With(fields...)}
+}
@@ -0,0 +1,60 @@
+// Package zerolog provides an atreugo.Logger implementation backed by
+// github.com/rs/zerolog, for use as Config.Logger.
+package zerolog
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog"
+	"github.com/savsgio/atreugo/v11"
+)
+
+type logger struct {
+	log zerolog.Logger
+}
+
+// New wraps log as an atreugo.Logger.
+func New(log zerolog.Logger) atreugo.Logger {
+	return &logger{log: log}
+}
+
+func (l *logger) Print(v ...interface{}) { l.log.Info().Msg(fmt.Sprint(v...)) }
+
+func (l *logger) Printf(format string, args ...interface{}) {
+	l.log.Info().Msgf(format, args...)
+}
+
+func (l *logger) Debug(args ...interface{}) { l.log.Debug().Msg(fmt.Sprint(args...)) }
+func (l *logger) Debugf(format string, args ...interface{}) {
+	l.log.Debug().Msgf(format, args...)
+}
+
+func (l *logger) Info(args ...interface{}) { l.log.Info().Msg(fmt.Sprint(args...)) }
+func (l *logger) Infof(format string, args ...interface{}) {
+	l.log.Info().Msgf(format, args...)
+}
+
+func (l *logger) Warn(args ...interface{}) { l.log.Warn().Msg(fmt.Sprint(args...)) }
+func (l *logger) Warnf(format string, args ...interface{}) {
+	l.log.Warn().Msgf(format, args...)
+}
+
+func (l *logger) Error(args ...interface{}) { l.log.Error().Msg(fmt.Sprint(args...)) }
+func (l *logger) Errorf(format string, args ...interface{}) {
+	l.log.Error().Msgf(format, args...)
+}
+
+func (l *logger) With(fields ...interface{}) atreugo.Logger {
+	ctx := l.log.With()
+
+	for i := 0; i+1 < len(fields); i += 2 {
+		key, ok := fields[i].(string)
+		if !ok {
+			continue
+		}
+
+		ctx = ctx.Interface(key, fields[i+1])
+	}
+
+	return &logger{log: ctx.Logger()}
+}